@@ -0,0 +1,84 @@
+package sitter
+
+// EachContentPart walks n's byte range in source and interleaves the two
+// kinds of content a node is built from: raw source text and child nodes.
+// It calls onText for every slice of source between two children (or before
+// the first / after the last one), and onChild for each direct child in
+// order, ending at n's EndByte(). This is the common building block for code
+// transformers and redactors (templating, secret scrubbing, source
+// rewriting) that would otherwise have to reimplement this byte arithmetic
+// themselves, including the easy-to-miss edge cases around zero-width
+// children and text trailing the last child.
+//
+// Iteration stops and EachContentPart returns the error as soon as either
+// callback returns one.
+func (n *Node) EachContentPart(source []byte, onText func(string) error, onChild func(*Node) error) error {
+	pos := n.StartByte()
+
+	count := n.ChildCount()
+	for i := uint32(0); i < count; i++ {
+		child := n.Child(int(i))
+
+		if child.StartByte() > pos {
+			if err := onText(string(source[pos:child.StartByte()])); err != nil {
+				return err
+			}
+		}
+		if err := onChild(child); err != nil {
+			return err
+		}
+		pos = child.EndByte()
+	}
+
+	if pos < n.EndByte() {
+		return onText(string(source[pos:n.EndByte()]))
+	}
+	return nil
+}
+
+// EachContentPart is the TreeCursor mirror of (*Node).EachContentPart: it
+// interleaves onText and onChild over the current node's children using
+// GoToFirstChild/GoToNextSibling instead of indexed Child() lookups, which
+// callers already iterating with a cursor (e.g. over an included-range tree)
+// can use without giving up their traversal position. The cursor is left
+// pointing at the node it started on.
+func (c *TreeCursor) EachContentPart(source []byte, onText func(string) error, onChild func(*Node) error) error {
+	parent := c.CurrentNode()
+	pos := parent.StartByte()
+
+	if !c.GoToFirstChild() {
+		if pos < parent.EndByte() {
+			return onText(string(source[pos:parent.EndByte()]))
+		}
+		return nil
+	}
+
+	err := func() error {
+		for {
+			child := c.CurrentNode()
+
+			if child.StartByte() > pos {
+				if err := onText(string(source[pos:child.StartByte()])); err != nil {
+					return err
+				}
+			}
+			if err := onChild(child); err != nil {
+				return err
+			}
+			pos = child.EndByte()
+
+			if !c.GoToNextSibling() {
+				return nil
+			}
+		}
+	}()
+	c.GoToParent()
+	if err != nil {
+		return err
+	}
+
+	if pos < parent.EndByte() {
+		return onText(string(source[pos:parent.EndByte()]))
+	}
+	return nil
+}
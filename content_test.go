@@ -0,0 +1,108 @@
+package sitter
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEachContentPart(t *testing.T) {
+	assert := assert.New(t)
+
+	source := []byte("1 + 2")
+	n := Parse(source, getTestGrammar())
+	sum := n.NamedChild(0)
+	assert.Equal("sum", sum.Type())
+
+	var texts []string
+	var childTypes []string
+	err := sum.EachContentPart(source,
+		func(s string) error {
+			texts = append(texts, s)
+			return nil
+		},
+		func(child *Node) error {
+			childTypes = append(childTypes, child.Type())
+			return nil
+		},
+	)
+
+	assert.Nil(err)
+	assert.Equal([]string{"expression", "+", "expression"}, childTypes)
+	assert.Equal([]string{" ", " "}, texts)
+}
+
+func TestEachContentPartLeaf(t *testing.T) {
+	assert := assert.New(t)
+
+	source := []byte("1 + 2")
+	n := Parse(source, getTestGrammar())
+	number := n.NamedChild(0).NamedChild(0).NamedChild(0)
+	assert.Equal("number", number.Type())
+	assert.Equal(uint32(0), number.ChildCount())
+
+	var texts []string
+	var calledChild bool
+	err := number.EachContentPart(source,
+		func(s string) error {
+			texts = append(texts, s)
+			return nil
+		},
+		func(child *Node) error {
+			calledChild = true
+			return nil
+		},
+	)
+
+	assert.Nil(err)
+	assert.False(calledChild)
+	assert.Equal([]string{"1"}, texts)
+}
+
+func TestEachContentPartStopsOnError(t *testing.T) {
+	assert := assert.New(t)
+
+	source := []byte("1 + 2")
+	n := Parse(source, getTestGrammar())
+	sum := n.NamedChild(0)
+
+	wantErr := errors.New("stop")
+	var seen int
+	err := sum.EachContentPart(source,
+		func(s string) error { return nil },
+		func(child *Node) error {
+			seen++
+			if seen == 2 {
+				return wantErr
+			}
+			return nil
+		},
+	)
+
+	assert.Equal(wantErr, err)
+	assert.Equal(2, seen)
+}
+
+func TestTreeCursorEachContentPart(t *testing.T) {
+	assert := assert.New(t)
+
+	source := []byte("1 + 2")
+	n := Parse(source, getTestGrammar())
+	sum := n.NamedChild(0)
+	c := NewTreeCursor(sum)
+
+	var childTypes []string
+	err := c.EachContentPart(source,
+		func(s string) error { return nil },
+		func(child *Node) error {
+			childTypes = append(childTypes, child.Type())
+			return nil
+		},
+	)
+
+	assert.Nil(err)
+	assert.Equal([]string{"expression", "+", "expression"}, childTypes)
+	// the cursor must still be on the node it started on
+	assert.True(c.CurrentNode() == sum)
+}
@@ -0,0 +1,160 @@
+package sitter
+
+/*
+#include "bindings.h"
+#include <stdbool.h>
+
+extern bool goQueryCursorProgress(TSQueryCursorState *state);
+
+static inline void sitter_set_query_progress_callback(TSQueryCursor *cursor) {
+	ts_query_cursor_set_progress_callback(cursor, goQueryCursorProgress);
+}
+
+static inline void sitter_clear_query_progress_callback(TSQueryCursor *cursor) {
+	ts_query_cursor_set_progress_callback(cursor, NULL);
+}
+*/
+import "C"
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unsafe"
+)
+
+// ErrParseAborted is returned by ParseCtx when the underlying parse comes
+// back with a nil tree for a reason other than ctx being done — e.g.
+// SetOperationLimit or SetTimeout tripping instead. It exists so callers can
+// still rely on Go's "nil error means a valid result" convention instead of
+// having to nil-check the tree themselves.
+var ErrParseAborted = errors.New("tree-sitter: parse aborted")
+
+// ParseCtx behaves like ParseString, except that it polls ctx while parsing
+// and aborts the parse, returning ctx.Err(), as soon as ctx is done. This
+// makes it safe to parse untrusted or pathological input in a server or LSP
+// without pinning a goroutine forever: a request timeout or a client
+// disconnect frees the parser immediately instead of waiting for
+// SetOperationLimit or SetTimeout to eventually trip.
+//
+// Internally this installs the same tree-sitter cancellation flag
+// ts_parser_parse already polls between parse steps, and flips it from a
+// background goroutine the moment ctx.Done() fires.
+func (p *Parser) ParseCtx(ctx context.Context, oldTree *Tree, input []byte) (*Tree, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var cancelFlag uintptr
+	C.ts_parser_set_cancellation_flag(p.c, (*C.size_t)(unsafe.Pointer(&cancelFlag)))
+	defer C.ts_parser_set_cancellation_flag(p.c, nil)
+
+	if done := ctx.Done(); done != nil {
+		stop := make(chan struct{})
+		defer close(stop)
+
+		go func() {
+			select {
+			case <-done:
+				atomic.StoreUintptr(&cancelFlag, 1)
+			case <-stop:
+			}
+		}()
+	}
+
+	tree := p.ParseString(oldTree, input)
+	if tree == nil {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		return nil, ErrParseAborted
+	}
+	return tree, nil
+}
+
+// SetTimeout bounds how long a single ParseString/ParseCtx call may run
+// before tree-sitter gives up and returns a nil tree, using the library's
+// own microsecond-resolution deadline rather than Go-side polling. It
+// composes cleanly with ParseCtx: whichever of the context or the timeout
+// elapses first wins.
+func (p *Parser) SetTimeout(d time.Duration) {
+	C.ts_parser_set_timeout_micros(p.c, C.uint64_t(d.Microseconds()))
+}
+
+// queryCtxs maps a native query cursor pointer to the context.Context that
+// ExecCtx armed it with. It's keyed by the C pointer, not the Go
+// *QueryCursor, because ts_query_cursor_set_progress_callback hands the
+// callback a TSQueryCursorState carrying only the former.
+var queryCtxs sync.Map // map[uintptr]context.Context
+
+//export goQueryCursorProgress
+func goQueryCursorProgress(state *C.TSQueryCursorState) C.bool {
+	v, ok := queryCtxs.Load(uintptr(unsafe.Pointer(state.query_cursor)))
+	if !ok {
+		return false
+	}
+	return C.bool(v.(context.Context).Err() != nil)
+}
+
+// ExecCtx is the context-aware equivalent of Exec: it arms the native query
+// cursor's progress callback (tree-sitter's cancellation hook for queries,
+// the query-side counterpart of the cancellation flag ParseCtx installs on
+// the parser) so that it aborts
+// ts_query_cursor_next_match mid-call as soon as ctx is done, instead of a
+// pathological query pinning the calling goroutine across many thousands of
+// matches. Call NextMatchCtx, not NextMatch, to observe the cancellation.
+func (qc *QueryCursor) ExecCtx(ctx context.Context, q *Query, n *Node) {
+	key := uintptr(unsafe.Pointer(qc.c))
+	queryCtxs.Store(key, ctx)
+	// Drop the map entry once ctx is done, even if the caller abandons the
+	// cursor mid-iteration (e.g. an error path that never drains NextMatchCtx
+	// to completion). A context that is never done, such as
+	// context.Background(), keeps the entry of an abandoned cursor forever.
+	// This rides context.AfterFunc rather than runtime.SetFinalizer: qc
+	// already carries NewQueryCursor's own finalizer that frees the native
+	// TSQueryCursor, and SetFinalizer allows only one finalizer per object,
+	// so registering a second one here would silently replace that one and
+	// leak the cursor.
+	context.AfterFunc(ctx, func() { queryCtxs.Delete(key) })
+
+	C.sitter_set_query_progress_callback(qc.c)
+	qc.Exec(q, n)
+}
+
+// NextMatchCtx is NextMatch's context-aware counterpart: it returns
+// ctx.Err() once the context passed to ExecCtx is done — whether that
+// happened before this call, or mid-call via the progress callback
+// installed by ExecCtx aborting the underlying ts_query_cursor_next_match.
+// The cursor's entry in ExecCtx's bookkeeping is released as soon as
+// iteration ends, successfully or not.
+func (qc *QueryCursor) NextMatchCtx() (*QueryMatch, bool, error) {
+	key := uintptr(unsafe.Pointer(qc.c))
+
+	ctx, armed := queryCtxs.Load(key)
+	if armed {
+		if err := ctx.(context.Context).Err(); err != nil {
+			qc.clearCtx(key)
+			return nil, false, err
+		}
+	}
+
+	m, ok := qc.NextMatch()
+
+	if armed {
+		if err := ctx.(context.Context).Err(); err != nil {
+			qc.clearCtx(key)
+			return nil, false, err
+		}
+	}
+	if !ok {
+		qc.clearCtx(key)
+	}
+	return m, ok, nil
+}
+
+func (qc *QueryCursor) clearCtx(key uintptr) {
+	C.sitter_clear_query_progress_callback(qc.c)
+	queryCtxs.Delete(key)
+}
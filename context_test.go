@@ -0,0 +1,100 @@
+package sitter
+
+import (
+	"context"
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCtx(t *testing.T) {
+	assert := assert.New(t)
+
+	parser := NewParser()
+	parser.SetLanguage(getTestGrammar())
+
+	tree, err := parser.ParseCtx(context.Background(), nil, []byte("1 + 2"))
+	assert.Nil(err)
+	assert.NotNil(tree)
+	assert.Equal("expression", tree.RootNode().Type())
+}
+
+func TestParseCtxAlreadyCancelled(t *testing.T) {
+	assert := assert.New(t)
+
+	parser := NewParser()
+	parser.SetLanguage(getTestGrammar())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tree, err := parser.ParseCtx(ctx, nil, []byte("1 + 2"))
+	assert.Nil(tree)
+	assert.Equal(context.Canceled, err)
+}
+
+func TestParseCtxOperationLimitAborted(t *testing.T) {
+	assert := assert.New(t)
+
+	parser := NewParser()
+	parser.SetLanguage(getTestGrammar())
+	parser.SetOperationLimit(1)
+
+	tree, err := parser.ParseCtx(context.Background(), nil, []byte("1 + 2"))
+	assert.Nil(tree)
+	assert.Equal(ErrParseAborted, err)
+}
+
+func TestQueryCursorNextMatchCtx(t *testing.T) {
+	assert := assert.New(t)
+
+	parser := NewParser()
+	parser.SetLanguage(getTestGrammar())
+	tree := parser.ParseString(nil, []byte("1 + 2"))
+
+	q, err := NewQuery([]byte("(sum) (number)"), getTestGrammar())
+	assert.Nil(err)
+
+	qc := NewQueryCursor()
+	qc.ExecCtx(context.Background(), q, tree.RootNode())
+
+	var matched int
+	for {
+		_, ok, err := qc.NextMatchCtx()
+		assert.Nil(err)
+		if !ok {
+			break
+		}
+		matched++
+	}
+	assert.Equal(3, matched)
+
+	// a fully-drained cursor must not keep its context registered forever
+	_, tracked := queryCtxs.Load(uintptr(unsafe.Pointer(qc.c)))
+	assert.False(tracked)
+}
+
+func TestQueryCursorNextMatchCtxCancelled(t *testing.T) {
+	assert := assert.New(t)
+
+	parser := NewParser()
+	parser.SetLanguage(getTestGrammar())
+	tree := parser.ParseString(nil, []byte("1 + 2"))
+
+	q, err := NewQuery([]byte("(sum) (number)"), getTestGrammar())
+	assert.Nil(err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	qc := NewQueryCursor()
+	qc.ExecCtx(ctx, q, tree.RootNode())
+	cancel()
+
+	_, ok, err := qc.NextMatchCtx()
+	assert.False(ok)
+	assert.Equal(context.Canceled, err)
+
+	// cancellation must release the bookkeeping too, not just report the error
+	_, tracked := queryCtxs.Load(uintptr(unsafe.Pointer(qc.c)))
+	assert.False(tracked)
+}
@@ -0,0 +1,8 @@
+package sitter
+
+// TestGrammar exposes the tiny arithmetic grammar used by this package's own
+// test suite so that subpackages (e.g. treediff) can build real parse trees
+// in their tests without depending on a full language binding.
+func TestGrammar() *Language {
+	return getTestGrammar()
+}
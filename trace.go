@@ -0,0 +1,331 @@
+package sitter
+
+/*
+#include "bindings.h"
+
+extern void goTraceLog(void *payload, TSLogType log_type, const char *buffer);
+
+static inline void sitter_set_logger(TSParser *parser, void *payload) {
+	TSLogger logger;
+	logger.payload = payload;
+	logger.log = goTraceLog;
+	ts_parser_set_logger(parser, logger);
+}
+
+static inline void sitter_clear_logger(TSParser *parser) {
+	TSLogger logger = {0};
+	ts_parser_set_logger(parser, logger);
+}
+*/
+import "C"
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"runtime/cgo"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+	"unsafe"
+)
+
+// TraceEventKind classifies a single step a Parser takes while processing
+// input, inferred from the text of the underlying tree-sitter log line.
+type TraceEventKind int
+
+const (
+	TraceLex TraceEventKind = iota
+	TraceShift
+	TraceReduce
+	TraceErrorRecovery
+	TraceIncludedRangeSwitch
+	TraceOther
+)
+
+func (k TraceEventKind) String() string {
+	switch k {
+	case TraceLex:
+		return "lex"
+	case TraceShift:
+		return "shift"
+	case TraceReduce:
+		return "reduce"
+	case TraceErrorRecovery:
+		return "error-recovery"
+	case TraceIncludedRangeSwitch:
+		return "included-range-switch"
+	default:
+		return "other"
+	}
+}
+
+// TraceEvent is a single entry in a Parser's trace stream.
+//
+// ByteOffset and Point track the lexer's position in the source, derived by
+// watching the "consume"/"skip" log lines go by and advancing past each
+// character they report by its encoded UTF-8 length (the logger itself has
+// no direct position field). Symbol is the grammar symbol name tree-sitter's
+// log line names (the "sym:" field of e.g. a reduce or lexed_lookahead line;
+// a numeric id there is resolved through the parser's language), or "" when
+// the line doesn't name one. Depth
+// tracks the parser's shift/reduce stack depth: it increases by one on each
+// shift and decreases by child_count-1 on each reduce, mirroring the nesting
+// of grammar rules the way participle's parse trace indents by rule depth.
+type TraceEvent struct {
+	Kind       TraceEventKind
+	Message    string
+	ByteOffset uint32
+	Point      Point
+	Symbol     string
+	Depth      int
+}
+
+func classifyTrace(msg string) TraceEventKind {
+	switch {
+	case strings.Contains(msg, "error"):
+		return TraceErrorRecovery
+	case strings.Contains(msg, "included_range"):
+		return TraceIncludedRangeSwitch
+	case strings.Contains(msg, "shift"):
+		return TraceShift
+	case strings.Contains(msg, "reduce") || strings.Contains(msg, "accept"):
+		return TraceReduce
+	case strings.Contains(msg, "lex"):
+		return TraceLex
+	default:
+		return TraceOther
+	}
+}
+
+// extractChar pulls the character out of a "... character:'x' ..." log line,
+// as emitted for each "consume" and "skip" step, or out of the
+// "... character:N ..." form (a decimal code point) used for characters
+// outside printable ASCII, such as a newline.
+func extractChar(msg string) (rune, bool) {
+	const marker = "character:"
+	i := strings.Index(msg, marker)
+	if i < 0 {
+		return 0, false
+	}
+	rest := msg[i+len(marker):]
+	if strings.HasPrefix(rest, "'") {
+		r, size := utf8.DecodeRuneInString(rest[1:])
+		if size == 0 || r == utf8.RuneError {
+			return 0, false
+		}
+		return r, true
+	}
+	end := strings.IndexFunc(rest, func(r rune) bool { return r < '0' || r > '9' })
+	if end < 0 {
+		end = len(rest)
+	}
+	n, err := strconv.Atoi(rest[:end])
+	if err != nil {
+		return 0, false
+	}
+	return rune(n), true
+}
+
+// extractSymbol pulls the symbol out of a "... sym:X ..." log line, as
+// emitted for reduce and lexed_lookahead steps. X is returned as is when it
+// is a symbol name; a numeric X is resolved through lang.
+func extractSymbol(msg string, lang *Language) string {
+	const marker = "sym:"
+	i := strings.Index(msg, marker)
+	if i < 0 {
+		return ""
+	}
+	rest := msg[i+len(marker):]
+	if j := strings.Index(rest, ", "); j >= 0 {
+		rest = rest[:j]
+	}
+	end := strings.IndexFunc(rest, func(r rune) bool { return r < '0' || r > '9' })
+	if rest == "" || end >= 0 {
+		return rest
+	}
+	if lang == nil {
+		return ""
+	}
+	id, err := strconv.Atoi(rest)
+	if err != nil {
+		return ""
+	}
+	return lang.SymbolName(Symbol(id))
+}
+
+// extractChildCount pulls the numeric count out of a "... child_count:N ..."
+// log line, as emitted for reduce steps, to tell how many stack entries the
+// reduce collapses into one.
+func extractChildCount(msg string) (int, bool) {
+	const marker = "child_count:"
+	i := strings.Index(msg, marker)
+	if i < 0 {
+		return 0, false
+	}
+	rest := msg[i+len(marker):]
+	end := strings.IndexFunc(rest, func(r rune) bool { return r < '0' || r > '9' })
+	if end == 0 {
+		return 0, false
+	}
+	if end < 0 {
+		end = len(rest)
+	}
+	n, err := strconv.Atoi(rest[:end])
+	if err != nil {
+		return 0, false
+	}
+	return n, true
+}
+
+type traceState struct {
+	writer  io.Writer
+	handler func(TraceEvent)
+	lang    *Language
+
+	depth  int
+	offset uint32
+	point  Point
+}
+
+func (s *traceState) advance(msg string) TraceEvent {
+	switch {
+	case strings.HasPrefix(msg, "shift"):
+		s.depth++
+	case strings.HasPrefix(msg, "reduce"):
+		if n, ok := extractChildCount(msg); ok {
+			s.depth -= n - 1
+		}
+	}
+	if s.depth < 0 {
+		s.depth = 0
+	}
+
+	if r, ok := extractChar(msg); ok && (strings.HasPrefix(msg, "consume") || strings.HasPrefix(msg, "skip")) {
+		if n := utf8.RuneLen(r); n > 0 {
+			s.offset += uint32(n)
+		} else {
+			s.offset++
+		}
+		if r == '\n' {
+			s.point.Row++
+			s.point.Column = 0
+		} else {
+			s.point.Column++
+		}
+	}
+
+	return TraceEvent{
+		Kind:       classifyTrace(msg),
+		Message:    msg,
+		ByteOffset: s.offset,
+		Point:      s.point,
+		Symbol:     extractSymbol(msg, s.lang),
+		Depth:      s.depth,
+	}
+}
+
+// traceHandles maps a native parser pointer to the cgo.Handle SetTrace/
+// SetTraceHandler armed it with. It's keyed by the C pointer, not the Go
+// *Parser, so that a parser dropped without an explicit SetTrace(nil) (e.g.
+// on an error path) doesn't keep itself GC-rooted forever through this map.
+var traceHandles sync.Map // map[uintptr]cgo.Handle
+
+//export goTraceLog
+func goTraceLog(payload unsafe.Pointer, logType C.TSLogType, buffer *C.char) {
+	state, ok := cgo.Handle(uintptr(payload)).Value().(*traceState)
+	if !ok {
+		return
+	}
+
+	ev := state.advance(C.GoString(buffer))
+
+	if state.writer != nil {
+		fmt.Fprintf(state.writer, "%s%s byte:%d point:%d,%d sym:%q: %s\n",
+			strings.Repeat("  ", ev.Depth), ev.Kind, ev.ByteOffset, ev.Point.Row, ev.Point.Column, ev.Symbol, ev.Message)
+	}
+	if state.handler != nil {
+		state.handler(ev)
+	}
+}
+
+// SetTrace makes the parser write one line per parse step (shift, reduce,
+// error recovery, lexer lookahead, included-range switch) to w, indented by
+// nesting depth the way participle's parse trace is. It wires up the same
+// per-step logging tree-sitter exposes through ts_parser_set_logger, so a
+// grammar that's hard to reason about from the resulting tree alone (a
+// SetOperationLimit that fires too early, an unexpected SetIncludedRanges
+// result, an ambiguous precedence) can be debugged without patching the
+// binding. Passing a nil w disables tracing.
+func (p *Parser) SetTrace(w io.Writer) {
+	if w == nil {
+		p.clearTrace()
+		return
+	}
+	p.setTrace(&traceState{writer: w, lang: p.Language()})
+}
+
+// SetTraceHandler is the structured equivalent of SetTrace: handler is
+// called once per parse step with a TraceEvent instead of a formatted line.
+// Passing a nil handler disables tracing.
+func (p *Parser) SetTraceHandler(handler func(TraceEvent)) {
+	if handler == nil {
+		p.clearTrace()
+		return
+	}
+	p.setTrace(&traceState{handler: handler, lang: p.Language()})
+}
+
+func (p *Parser) setTrace(state *traceState) {
+	p.clearTrace()
+
+	h := cgo.NewHandle(state)
+	traceHandles.Store(uintptr(unsafe.Pointer(p.c)), h)
+	C.sitter_set_logger(p.c, unsafe.Pointer(uintptr(h)))
+}
+
+func (p *Parser) clearTrace() {
+	C.sitter_clear_logger(p.c)
+	if v, ok := traceHandles.LoadAndDelete(uintptr(unsafe.Pointer(p.c))); ok {
+		v.(cgo.Handle).Delete()
+	}
+}
+
+// dotGraphPipes maps a native parser pointer to the write end of the OS pipe
+// PrintDotGraphs pointed ts_parser_print_dot_graphs at. It's keyed by the C
+// pointer for the same reason as traceHandles: keying by *Parser would pin
+// the Go object in this GC-rooted map for any parser that never calls
+// PrintDotGraphs(nil) before being dropped.
+var dotGraphPipes sync.Map // map[uintptr]*os.File
+
+// PrintDotGraphs makes the parser write a DOT graph of its internal state to
+// w after every subsequent parse — the same data `tree-sitter parse
+// --debug-graph` prints — by pointing the C library's
+// ts_parser_print_dot_graphs at the write end of an OS pipe and copying it
+// to w in the background. Passing a nil w disables it.
+func (p *Parser) PrintDotGraphs(w io.Writer) error {
+	p.stopPrintingDotGraphs()
+
+	if w == nil {
+		return nil
+	}
+
+	r, wr, err := os.Pipe()
+	if err != nil {
+		return err
+	}
+
+	C.ts_parser_print_dot_graphs(p.c, C.int(wr.Fd()))
+	dotGraphPipes.Store(uintptr(unsafe.Pointer(p.c)), wr)
+
+	go io.Copy(w, r)
+	return nil
+}
+
+func (p *Parser) stopPrintingDotGraphs() {
+	C.ts_parser_print_dot_graphs(p.c, -1)
+	if v, ok := dotGraphPipes.LoadAndDelete(uintptr(unsafe.Pointer(p.c))); ok {
+		v.(*os.File).Close()
+	}
+}
@@ -0,0 +1,127 @@
+package sitter
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetTrace(t *testing.T) {
+	assert := assert.New(t)
+
+	parser := NewParser()
+	parser.SetLanguage(getTestGrammar())
+
+	var buf bytes.Buffer
+	parser.SetTrace(&buf)
+	parser.ParseString(nil, []byte("1 + 2"))
+	parser.SetTrace(nil)
+
+	first := buf.String()
+	assert.NotEmpty(first)
+
+	var buf2 bytes.Buffer
+	parser.SetTrace(&buf2)
+	parser.ParseString(nil, []byte("1 + 2"))
+	parser.SetTrace(nil)
+
+	assert.Equal(first, buf2.String())
+}
+
+func TestSetTraceHandler(t *testing.T) {
+	assert := assert.New(t)
+
+	parser := NewParser()
+	parser.SetLanguage(getTestGrammar())
+
+	var events []TraceEvent
+	parser.SetTraceHandler(func(ev TraceEvent) {
+		events = append(events, ev)
+	})
+	parser.ParseString(nil, []byte("1 + 2"))
+	parser.SetTraceHandler(nil)
+
+	assert.NotEmpty(events)
+
+	// "1 + 2" parses as expression(sum(expression(number) + expression(number))),
+	// which requires several shifts onto the parser's stack before the first
+	// reduce pops any of them back off. Depth should climb past 1 as those
+	// shifts accumulate, then come back down as the matching reduces fire --
+	// a real stack depth, not a fixed 0/1 lex-vs-parse flag.
+	var maxDepth int
+	var sawDecrease bool
+	var maxOffset uint32
+	for i, ev := range events {
+		if ev.Depth > maxDepth {
+			maxDepth = ev.Depth
+		}
+		if i > 0 && ev.Depth < events[i-1].Depth {
+			sawDecrease = true
+		}
+		if ev.ByteOffset > maxOffset {
+			maxOffset = ev.ByteOffset
+		}
+	}
+	assert.Greater(maxDepth, 1, "expected depth to climb past a fixed 0/1 toggle as shifts accumulate")
+	assert.True(sawDecrease, "expected depth to come back down as reduces pop the stack")
+	assert.Greater(maxOffset, uint32(0), "expected ByteOffset to advance past the start of the source")
+}
+
+func TestTraceStateAdvanceMultibyte(t *testing.T) {
+	assert := assert.New(t)
+
+	s := &traceState{}
+	ev := s.advance(`consume character:'a'`)
+	assert.Equal(uint32(1), ev.ByteOffset)
+
+	// '€' encodes as 3 bytes in UTF-8; the offset must advance by that many,
+	// not by a flat 1 per consumed rune.
+	ev = s.advance(`consume character:'€'`)
+	assert.Equal(uint32(4), ev.ByteOffset)
+}
+
+func TestPrintDotGraphs(t *testing.T) {
+	assert := assert.New(t)
+
+	parser := NewParser()
+	parser.SetLanguage(getTestGrammar())
+
+	buf := &syncBuffer{}
+	assert.Nil(parser.PrintDotGraphs(buf))
+	parser.ParseString(nil, []byte("1 + 2"))
+	assert.Nil(parser.PrintDotGraphs(nil)) // closes the pipe, unblocking the copy goroutine
+
+	deadline := time.Now().Add(2 * time.Second)
+	for buf.Len() == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	assert.NotEmpty(buf.String())
+}
+
+// syncBuffer lets the background io.Copy goroutine started by
+// PrintDotGraphs write concurrently with the test reading the result.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Len()
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
@@ -0,0 +1,243 @@
+// Package treediff computes structural differences between two parses of
+// related source text, e.g. the tree before and after an edit. It walks both
+// trees as merkletrie-style noders: each node's identity hash is derived
+// from its type, its field name within its parent, and either its source
+// bytes (leaves) or its children's hashes (interior nodes). Equal hashes let
+// Diff skip whole subtrees instead of comparing every node pairwise.
+package treediff
+
+import (
+	"crypto/sha256"
+
+	sitter "github.com/p-e-w/go-tree-sitter"
+)
+
+// Action describes how a node changed between the "from" and "to" trees
+// passed to Diff.
+type Action int
+
+const (
+	// Insert means the node in the new tree has no counterpart in the old one.
+	Insert Action = iota
+	// Delete means the node in the old tree has no counterpart in the new one.
+	Delete
+	// Modify means a node at the same position kept its type, but its
+	// content or one of its descendants changed.
+	Modify
+)
+
+func (a Action) String() string {
+	switch a {
+	case Insert:
+		return "Insert"
+	case Delete:
+		return "Delete"
+	case Modify:
+		return "Modify"
+	default:
+		return "Unknown"
+	}
+}
+
+// Change is a single structural difference between two parse trees. From and
+// To carry the byte ranges on either side (via their StartByte/EndByte),
+// whichever are applicable to Action; the other is nil. Path is the sequence
+// of field names (or, for unlabeled children, node types) leading from the
+// tree root down to the changed node.
+type Change struct {
+	Action Action
+	From   *sitter.Node
+	To     *sitter.Node
+	Path   []string
+}
+
+type hash [sha256.Size]byte
+
+// node mirrors one side of a tree in a form that's cheap to hash and
+// compare, decoupling the diff algorithm from cgo-backed *sitter.Node
+// traversal.
+type node struct {
+	n        *sitter.Node
+	field    string
+	children []*node
+	hash     hash
+}
+
+func build(source []byte, root *sitter.Node) *node {
+	return buildCursor(source, sitter.NewTreeCursor(root))
+}
+
+func buildCursor(source []byte, c *sitter.TreeCursor) *node {
+	cur := c.CurrentNode()
+	field := c.CurrentFieldName()
+
+	h := sha256.New()
+	h.Write([]byte(cur.Type()))
+	h.Write([]byte{0})
+	h.Write([]byte(field))
+	h.Write([]byte{0})
+
+	var children []*node
+	if c.GoToFirstChild() {
+		for {
+			child := buildCursor(source, c)
+			children = append(children, child)
+			h.Write(child.hash[:])
+			if !c.GoToNextSibling() {
+				break
+			}
+		}
+		c.GoToParent()
+	} else {
+		h.Write(source[cur.StartByte():cur.EndByte()])
+	}
+
+	var sum hash
+	copy(sum[:], h.Sum(nil))
+
+	return &node{n: cur, field: field, children: children, hash: sum}
+}
+
+// Diff compares fromTree (parsed from fromSource) with toTree (parsed from
+// toSource, typically the result of re-parsing fromTree after an edit) and
+// returns an ordered list of changes. Unchanged subtrees are skipped
+// entirely: Diff only descends into children whose hashes differ.
+func Diff(fromTree, toTree *sitter.Tree, fromSource, toSource []byte) []Change {
+	from := build(fromSource, fromTree.RootNode())
+	to := build(toSource, toTree.RootNode())
+
+	var changes []Change
+	diffNode(from, to, nil, &changes)
+	return changes
+}
+
+func diffNode(from, to *node, path []string, changes *[]Change) {
+	if from != nil && to != nil && from.hash == to.hash {
+		return
+	}
+
+	switch {
+	case from == nil:
+		*changes = append(*changes, Change{Action: Insert, To: to.n, Path: path})
+		return
+	case to == nil:
+		*changes = append(*changes, Change{Action: Delete, From: from.n, Path: path})
+		return
+	case from.n.Type() != to.n.Type():
+		*changes = append(*changes, Change{Action: Delete, From: from.n, Path: path})
+		*changes = append(*changes, Change{Action: Insert, To: to.n, Path: path})
+		return
+	}
+
+	*changes = append(*changes, Change{Action: Modify, From: from.n, To: to.n, Path: path})
+
+	for _, pair := range pairChildren(from.children, to.children) {
+		childPath := append(append([]string(nil), path...), label(pair[0], pair[1]))
+		diffNode(pair[0], pair[1], childPath, changes)
+	}
+}
+
+// pairChildren matches old and new children primarily by field name, since a
+// field name stays stable across reorderings and insertions elsewhere in the
+// same node, and falls back to ordinal position for children that carry no
+// field name and for new children whose field name has no match among the
+// old ones. An old named child left without a match is paired with nil
+// (reported as deleted); it does not join the positional pairing.
+func pairChildren(from, to []*node) [][2]*node {
+	fromByField := map[string][]*node{}
+	var fromFields []string
+	var fromPositional []*node
+	for _, n := range from {
+		if n.field != "" {
+			if len(fromByField[n.field]) == 0 {
+				fromFields = append(fromFields, n.field)
+			}
+			fromByField[n.field] = append(fromByField[n.field], n)
+		} else {
+			fromPositional = append(fromPositional, n)
+		}
+	}
+
+	var pairs [][2]*node
+	var toPositional []*node
+	for _, n := range to {
+		if n.field != "" && len(fromByField[n.field]) > 0 {
+			match := fromByField[n.field][0]
+			fromByField[n.field] = fromByField[n.field][1:]
+			pairs = append(pairs, [2]*node{match, n})
+		} else {
+			toPositional = append(toPositional, n)
+		}
+	}
+
+	// Walk fromFields, not fromByField directly, so that fields left over
+	// after pairing (fully deleted on the to side) produce Delete changes in
+	// the order they first appeared in from.children, not Go's randomized
+	// map iteration order.
+	for _, field := range fromFields {
+		for _, n := range fromByField[field] {
+			pairs = append(pairs, [2]*node{n, nil})
+		}
+	}
+
+	i := 0
+	for ; i < len(fromPositional) && i < len(toPositional); i++ {
+		pairs = append(pairs, [2]*node{fromPositional[i], toPositional[i]})
+	}
+	for ; i < len(fromPositional); i++ {
+		pairs = append(pairs, [2]*node{fromPositional[i], nil})
+	}
+	for ; i < len(toPositional); i++ {
+		pairs = append(pairs, [2]*node{nil, toPositional[i]})
+	}
+
+	return pairs
+}
+
+func label(from, to *node) string {
+	switch {
+	case from != nil && from.field != "":
+		return from.field
+	case to != nil && to.field != "":
+		return to.field
+	case from != nil:
+		return from.n.Type()
+	case to != nil:
+		return to.n.Type()
+	default:
+		return ""
+	}
+}
+
+// PruneUnchanged drops changes that lie entirely inside the region edit
+// proves untouched, where edit is the same EditInput passed to Tree.Edit
+// before toTree was parsed. It uses each node's HasChanges() bit, which
+// tree-sitter already maintains for exactly this purpose, as a fast
+// pre-filter, and falls back to comparing byte ranges against edit for the
+// rare node that straddles the edited region without being marked changed.
+//
+// From nodes live in the old tree's coordinate space, bounded by
+// edit.OldEndIndex; To nodes live in the new tree's, bounded by
+// edit.NewEndIndex. Those two bounds differ whenever the edit's replacement
+// text isn't the same length as the text it replaced, so each side is
+// checked against its own bound rather than a single shared one.
+func PruneUnchanged(changes []Change, edit sitter.EditInput) []Change {
+	pruned := changes[:0]
+	for _, c := range changes {
+		if outsideEdit(c.From, edit.StartIndex, edit.OldEndIndex) && outsideEdit(c.To, edit.StartIndex, edit.NewEndIndex) {
+			continue
+		}
+		pruned = append(pruned, c)
+	}
+	return pruned
+}
+
+func outsideEdit(n *sitter.Node, startIndex, endIndex uint32) bool {
+	if n == nil {
+		return true
+	}
+	if n.HasChanges() {
+		return false
+	}
+	return n.EndByte() <= startIndex || n.StartByte() >= endIndex
+}
@@ -0,0 +1,214 @@
+package treediff
+
+import (
+	"testing"
+
+	sitter "github.com/p-e-w/go-tree-sitter"
+)
+
+func parse(t *testing.T, old *sitter.Tree, src string) *sitter.Tree {
+	t.Helper()
+
+	parser := sitter.NewParser()
+	parser.SetLanguage(sitter.TestGrammar())
+	return parser.ParseString(old, []byte(src))
+}
+
+func actions(changes []Change) []Action {
+	a := make([]Action, len(changes))
+	for i, c := range changes {
+		a[i] = c.Action
+	}
+	return a
+}
+
+func contains(actions []Action, a Action) bool {
+	for _, x := range actions {
+		if x == a {
+			return true
+		}
+	}
+	return false
+}
+
+// inPath reports whether label appears anywhere along path, i.e. whether the
+// change is at or below a child labeled label.
+func inPath(path []string, label string) bool {
+	for _, p := range path {
+		if p == label {
+			return true
+		}
+	}
+	return false
+}
+
+func TestDiffPureInsert(t *testing.T) {
+	fromSrc, toSrc := "1 + 2", "1 + 2\n//3 + 5"
+
+	fromTree := parse(t, nil, fromSrc)
+	toTree := parse(t, nil, toSrc)
+
+	changes := Diff(fromTree, toTree, []byte(fromSrc), []byte(toSrc))
+
+	var inserted *sitter.Node
+	for _, c := range changes {
+		if c.Action == Insert {
+			inserted = c.To
+		}
+		if c.Action == Delete {
+			t.Fatalf("unexpected Delete in a pure insert: %+v", c)
+		}
+	}
+	if inserted == nil {
+		t.Fatal("expected an Insert change for the new comment node")
+	}
+	if inserted.Type() != "comment" {
+		t.Fatalf("expected inserted node to be a comment, got %s", inserted.Type())
+	}
+}
+
+func TestDiffPureDelete(t *testing.T) {
+	fromSrc, toSrc := "1 + 2\n//3 + 5", "1 + 2"
+
+	fromTree := parse(t, nil, fromSrc)
+	toTree := parse(t, nil, toSrc)
+
+	changes := Diff(fromTree, toTree, []byte(fromSrc), []byte(toSrc))
+
+	var deleted *sitter.Node
+	for _, c := range changes {
+		if c.Action == Delete {
+			deleted = c.From
+		}
+		if c.Action == Insert {
+			t.Fatalf("unexpected Insert in a pure delete: %+v", c)
+		}
+	}
+	if deleted == nil {
+		t.Fatal("expected a Delete change for the removed comment node")
+	}
+	if deleted.Type() != "comment" {
+		t.Fatalf("expected deleted node to be a comment, got %s", deleted.Type())
+	}
+}
+
+func TestDiffNestedModify(t *testing.T) {
+	fromSrc, toSrc := "1 + 2", "1 + (3 + 3)"
+
+	fromTree := parse(t, nil, fromSrc)
+	toTree := parse(t, nil, toSrc)
+
+	changes := Diff(fromTree, toTree, []byte(fromSrc), []byte(toSrc))
+
+	if !contains(actions(changes), Modify) {
+		t.Fatalf("expected at least one Modify change, got %v", actions(changes))
+	}
+
+	for _, c := range changes {
+		if inPath(c.Path, "left") {
+			t.Fatalf("left operand was untouched, should not appear in the diff: %+v", c)
+		}
+	}
+}
+
+// TestPairChildrenLeftoverOrderIsStable covers a node with three distinct
+// fields that are all fully deleted on the to side (no counterpart at all):
+// pairChildren used to collect those leftovers by ranging over a map, so
+// their relative order in the returned pairs varied from run to run. It
+// must instead match the order the fields first appear in from.
+func TestPairChildrenLeftoverOrderIsStable(t *testing.T) {
+	left := &node{field: "left"}
+	op := &node{field: "operator"}
+	right := &node{field: "right"}
+	from := []*node{left, op, right}
+
+	for i := 0; i < 20; i++ {
+		pairs := pairChildren(from, nil)
+		if len(pairs) != 3 {
+			t.Fatalf("expected 3 leftover pairs, got %d", len(pairs))
+		}
+		got := []*node{pairs[0][0], pairs[1][0], pairs[2][0]}
+		want := []*node{left, op, right}
+		for j := range want {
+			if got[j] != want[j] {
+				t.Fatalf("run %d: leftover order = %v, want %v (first-seen field order)", i, got, want)
+			}
+		}
+	}
+}
+
+func TestPruneUnchanged(t *testing.T) {
+	fromSrc := "1 + 2"
+	fromTree := parse(t, nil, fromSrc)
+
+	toSrc := "1 + (3 + 3)"
+	fromTree.Edit(sitter.EditInput{
+		StartIndex:  4,
+		OldEndIndex: 5,
+		NewEndIndex: 11,
+		StartPoint:  sitter.Point{Row: 0, Column: 4},
+		OldEndPoint: sitter.Point{Row: 0, Column: 5},
+		NewEndPoint: sitter.Point{Row: 0, Column: 11},
+	})
+
+	parser := sitter.NewParser()
+	parser.SetLanguage(sitter.TestGrammar())
+	toTree := parser.ParseString(fromTree, []byte(toSrc))
+
+	changes := Diff(fromTree, toTree, []byte(fromSrc), []byte(toSrc))
+	edit := sitter.EditInput{StartIndex: 4, OldEndIndex: 5, NewEndIndex: 11}
+
+	pruned := PruneUnchanged(changes, edit)
+	if len(pruned) == 0 {
+		t.Fatal("expected changes touching the edited region to survive pruning")
+	}
+	for _, c := range pruned {
+		if inPath(c.Path, "left") {
+			t.Fatalf("pruning should have dropped the untouched left operand: %+v", c)
+		}
+	}
+}
+
+// TestPruneUnchangedShrinkingEdit covers a shrinking edit (NewEndIndex <
+// OldEndIndex), where From nodes (old tree) and To nodes (new tree) need
+// different upper bounds: the old tree's edited region ends at OldEndIndex,
+// not at the shorter NewEndIndex. It also has an untouched sibling (the
+// comment) after the edited region to make sure that one is unaffected by
+// the edit's bounds either way.
+func TestPruneUnchangedShrinkingEdit(t *testing.T) {
+	fromSrc := "1 + (3 + 3)\n//tail"
+	fromTree := parse(t, nil, fromSrc)
+
+	toSrc := "1 + 2\n//tail"
+	edit := sitter.EditInput{
+		StartIndex:  4,
+		OldEndIndex: 11,
+		NewEndIndex: 5,
+		StartPoint:  sitter.Point{Row: 0, Column: 4},
+		OldEndPoint: sitter.Point{Row: 0, Column: 11},
+		NewEndPoint: sitter.Point{Row: 0, Column: 5},
+	}
+	fromTree.Edit(edit)
+
+	parser := sitter.NewParser()
+	parser.SetLanguage(sitter.TestGrammar())
+	toTree := parser.ParseString(fromTree, []byte(toSrc))
+
+	changes := Diff(fromTree, toTree, []byte(fromSrc), []byte(toSrc))
+	if len(changes) == 0 {
+		t.Fatal("expected the shrunk right operand to produce changes")
+	}
+
+	pruned := PruneUnchanged(changes, edit)
+	if len(pruned) == 0 {
+		t.Fatal("expected changes from inside the shrunk old region ([StartIndex, OldEndIndex)) to survive pruning")
+	}
+	for _, c := range pruned {
+		if inPath(c.Path, "left") {
+			t.Fatalf("pruning should have dropped the untouched left operand: %+v", c)
+		}
+		if (c.From != nil && c.From.Type() == "comment") || (c.To != nil && c.To.Type() == "comment") {
+			t.Fatalf("the untouched trailing comment should never appear as a change: %+v", c)
+		}
+	}
+}
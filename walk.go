@@ -0,0 +1,131 @@
+package sitter
+
+import "iter"
+
+// WalkOrder selects when Walk calls visit relative to a node's children.
+type WalkOrder int
+
+const (
+	// PreOrder visits a node before its children.
+	PreOrder WalkOrder = iota
+	// PostOrder visits a node after its children.
+	PostOrder
+	// BothOrder visits a node both before and after its children.
+	BothOrder
+)
+
+// WalkAction tells Walk what to do after a visit call returns.
+type WalkAction int
+
+const (
+	// WalkContinue proceeds with the traversal as normal.
+	WalkContinue WalkAction = iota
+	// WalkSkipChildren skips the current node's children (its post-order
+	// visit, if any, still happens).
+	WalkSkipChildren
+	// WalkStop ends the traversal immediately.
+	WalkStop
+)
+
+// Walk traverses the tree rooted at c's current node, calling visit for each
+// node along with its field name (as seen from its parent) and its depth
+// relative to the node Walk started from. It only uses GoToFirstChild,
+// GoToNextSibling and GoToParent to move around, so it needs no recursion
+// and no per-node allocation; c is left back at the node it started on.
+func (c *TreeCursor) Walk(order WalkOrder, visit func(n *Node, fieldName string, depth int) WalkAction) error {
+	depth := 0
+	skipChildren := false
+
+	// stop moves c back up to the node Walk started from.
+	stop := func() error {
+		for ; depth > 0; depth-- {
+			c.GoToParent()
+		}
+		return nil
+	}
+
+	for {
+		node := c.CurrentNode()
+		field := c.CurrentFieldName()
+
+		if !skipChildren && (order == PreOrder || order == BothOrder) {
+			switch visit(node, field, depth) {
+			case WalkStop:
+				return stop()
+			case WalkSkipChildren:
+				skipChildren = true
+			}
+		}
+
+		descended := false
+		if !skipChildren && c.GoToFirstChild() {
+			depth++
+			descended = true
+		}
+		skipChildren = false
+
+		if descended {
+			continue
+		}
+
+		if order == PostOrder || order == BothOrder {
+			if visit(node, field, depth) == WalkStop {
+				return stop()
+			}
+		}
+
+		for {
+			if depth == 0 {
+				return nil
+			}
+			if c.GoToNextSibling() {
+				break
+			}
+			if !c.GoToParent() {
+				return nil
+			}
+			depth--
+
+			if order == PostOrder || order == BothOrder {
+				parent := c.CurrentNode()
+				if visit(parent, c.CurrentFieldName(), depth) == WalkStop {
+					return stop()
+				}
+			}
+		}
+	}
+}
+
+// AllOption configures All's traversal.
+type AllOption func(*allOptions)
+
+type allOptions struct {
+	namedOnly bool
+}
+
+// NamedOnly makes All skip anonymous (unnamed) nodes.
+func NamedOnly() AllOption {
+	return func(o *allOptions) { o.namedOnly = true }
+}
+
+// All returns a pre-order iter.Seq2 over every node reachable from c's
+// current position, paired with its field name, built on top of Walk so it
+// shares the same allocation-free traversal.
+func (c *TreeCursor) All(opts ...AllOption) iter.Seq2[*Node, string] {
+	var o allOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return func(yield func(*Node, string) bool) {
+		c.Walk(PreOrder, func(n *Node, field string, depth int) WalkAction {
+			if o.namedOnly && !n.IsNamed() {
+				return WalkContinue
+			}
+			if !yield(n, field) {
+				return WalkStop
+			}
+			return WalkContinue
+		})
+	}
+}
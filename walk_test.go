@@ -0,0 +1,127 @@
+package sitter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTreeCursorWalkPreOrder(t *testing.T) {
+	assert := assert.New(t)
+
+	root := Parse([]byte("1 + 2"), getTestGrammar())
+	c := NewTreeCursor(root)
+
+	var types []string
+	var depths []int
+	err := c.Walk(PreOrder, func(n *Node, field string, depth int) WalkAction {
+		types = append(types, n.Type())
+		depths = append(depths, depth)
+		return WalkContinue
+	})
+
+	assert.Nil(err)
+	assert.Equal([]string{"expression", "sum", "expression", "number", "+", "expression", "number"}, types)
+	assert.Equal([]int{0, 1, 2, 3, 2, 2, 3}, depths)
+	assert.True(c.CurrentNode() == root)
+}
+
+func TestTreeCursorWalkPostOrder(t *testing.T) {
+	assert := assert.New(t)
+
+	root := Parse([]byte("1 + 2"), getTestGrammar())
+	c := NewTreeCursor(root)
+
+	var types []string
+	var depths []int
+	err := c.Walk(PostOrder, func(n *Node, field string, depth int) WalkAction {
+		types = append(types, n.Type())
+		depths = append(depths, depth)
+		return WalkContinue
+	})
+
+	assert.Nil(err)
+	assert.Equal([]string{"number", "expression", "+", "number", "expression", "sum", "expression"}, types)
+	assert.Equal([]int{3, 2, 2, 3, 2, 1, 0}, depths)
+}
+
+func TestTreeCursorWalkBothOrder(t *testing.T) {
+	assert := assert.New(t)
+
+	root := Parse([]byte("1 + 2"), getTestGrammar())
+	c := NewTreeCursor(root)
+
+	var types []string
+	var depths []int
+	err := c.Walk(BothOrder, func(n *Node, field string, depth int) WalkAction {
+		types = append(types, n.Type())
+		depths = append(depths, depth)
+		return WalkContinue
+	})
+
+	assert.Nil(err)
+	// every node is visited twice (once before its children, once after),
+	// at the same depth both times.
+	assert.Equal([]string{
+		"expression", "sum", "expression", "number", "number", "expression",
+		"+", "+", "expression", "number", "number", "expression", "sum", "expression",
+	}, types)
+	assert.Equal([]int{0, 1, 2, 3, 3, 2, 2, 2, 2, 3, 3, 2, 1, 0}, depths)
+}
+
+func TestTreeCursorWalkSkipChildren(t *testing.T) {
+	assert := assert.New(t)
+
+	root := Parse([]byte("1 + 2"), getTestGrammar())
+	c := NewTreeCursor(root)
+
+	var types []string
+	err := c.Walk(PreOrder, func(n *Node, field string, depth int) WalkAction {
+		types = append(types, n.Type())
+		if n.Type() == "sum" {
+			return WalkSkipChildren
+		}
+		return WalkContinue
+	})
+
+	assert.Nil(err)
+	assert.Equal([]string{"expression", "sum"}, types)
+}
+
+func TestTreeCursorWalkStop(t *testing.T) {
+	assert := assert.New(t)
+
+	root := Parse([]byte("1 + 2"), getTestGrammar())
+	c := NewTreeCursor(root)
+
+	var types []string
+	err := c.Walk(PreOrder, func(n *Node, field string, depth int) WalkAction {
+		types = append(types, n.Type())
+		if n.Type() == "number" {
+			return WalkStop
+		}
+		return WalkContinue
+	})
+
+	assert.Nil(err)
+	assert.Equal([]string{"expression", "sum", "expression", "number"}, types)
+}
+
+func TestTreeCursorAll(t *testing.T) {
+	assert := assert.New(t)
+
+	root := Parse([]byte("1 + 2"), getTestGrammar())
+	c := NewTreeCursor(root)
+
+	var types []string
+	for n, _ := range c.All() {
+		types = append(types, n.Type())
+	}
+	assert.Contains(types, "+")
+
+	types = nil
+	for n, _ := range c.All(NamedOnly()) {
+		types = append(types, n.Type())
+	}
+	assert.NotContains(types, "+")
+}